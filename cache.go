@@ -0,0 +1,218 @@
+package main
+
+import (
+	"container/list"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	cacheSize   int
+	cacheMaxTTL uint32
+	cacheMinTTL uint32
+
+	cacheHits   uint64
+	cacheMisses uint64
+
+	respCache *responseCache
+)
+
+// cacheKey identifies a cached response the same way a resolver cache
+// normally would: by question name, type, and class.
+type cacheKey struct {
+	qname  string
+	qtype  uint16
+	qclass uint16
+}
+
+func cacheKeyFor(q dns.Question) cacheKey {
+	return cacheKey{qname: strings.ToLower(q.Name), qtype: q.Qtype, qclass: q.Qclass}
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	msg     *dns.Msg
+	expires time.Time
+}
+
+// responseCache is a fixed-capacity, TTL-aware LRU cache of forwarded
+// responses, including negative (NXDOMAIN/NODATA) responses per RFC 2308.
+type responseCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[cacheKey]*list.Element
+}
+
+func newResponseCache(capacity int) *responseCache {
+	return &responseCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[cacheKey]*list.Element),
+	}
+}
+
+// get returns a copy of the cached response for key, with its record TTLs
+// decremented to reflect the time already spent in cache, so downstream
+// resolvers see accurate values.
+func (c *responseCache) get(key cacheKey) (*dns.Msg, bool) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		atomic.AddUint64(&cacheMisses, 1)
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	remaining := time.Until(entry.expires)
+	if remaining <= 0 {
+		c.removeElement(elem)
+		c.mu.Unlock()
+		atomic.AddUint64(&cacheMisses, 1)
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	msg := entry.msg.Copy()
+	c.mu.Unlock()
+
+	atomic.AddUint64(&cacheHits, 1)
+	decrementTTLs(msg, uint32(remaining.Seconds()))
+	return msg, true
+}
+
+// set stores a copy of msg under key for ttl seconds, evicting the least
+// recently used entry once the cache is at capacity.
+func (c *responseCache) set(key cacheKey, msg *dns.Msg, ttl uint32) {
+	if ttl == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(time.Duration(ttl) * time.Second)
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		elem.Value.(*cacheEntry).msg = msg.Copy()
+		elem.Value.(*cacheEntry).expires = expires
+		return
+	}
+
+	elem := c.ll.PushFront(&cacheEntry{key: key, msg: msg.Copy(), expires: expires})
+	c.items[key] = elem
+
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *responseCache) removeElement(elem *list.Element) {
+	if elem == nil {
+		return
+	}
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*cacheEntry).key)
+}
+
+// decrementTTLs caps every record's TTL to maxTTL, used to reflect time
+// already spent in cache on a hit.
+func decrementTTLs(msg *dns.Msg, maxTTL uint32) {
+	for _, section := range [][]dns.RR{msg.Answer, msg.Ns, msg.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			if rr.Header().Ttl > maxTTL {
+				rr.Header().Ttl = maxTTL
+			}
+		}
+	}
+}
+
+// cacheTTL computes how long a forwarded response should be cached: the
+// minimum TTL across its answer/authority/additional sections for a
+// positive response, or the SOA MINIMUM from the authority section for a
+// negative (NXDOMAIN/NODATA) response per RFC 2308, clamped to
+// [cacheMinTTL, cacheMaxTTL].
+func cacheTTL(resp *dns.Msg) uint32 {
+	var ttl uint32
+
+	isNegative := resp.Rcode == dns.RcodeNameError || (resp.Rcode == dns.RcodeSuccess && len(resp.Answer) == 0)
+	if isNegative {
+		for _, rr := range resp.Ns {
+			if soa, ok := rr.(*dns.SOA); ok {
+				ttl = soa.Minttl
+				break
+			}
+		}
+	} else {
+		found := false
+		for _, section := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+			sectionMin, ok := minTTLOf(section)
+			if ok && (!found || sectionMin < ttl) {
+				ttl = sectionMin
+				found = true
+			}
+		}
+	}
+
+	if ttl < cacheMinTTL {
+		ttl = cacheMinTTL
+	}
+	if cacheMaxTTL > 0 && ttl > cacheMaxTTL {
+		ttl = cacheMaxTTL
+	}
+	return ttl
+}
+
+// minTTLOf returns the smallest TTL among rrs, ignoring OPT pseudo-records,
+// and reports whether rrs contained any record to derive a TTL from. A bool
+// result (rather than treating a 0 floor as "no value yet") is required
+// because 0 is itself a legitimate TTL.
+func minTTLOf(rrs []dns.RR) (min uint32, ok bool) {
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == dns.TypeOPT {
+			continue
+		}
+		ttl := rr.Header().Ttl
+		if !ok || ttl < min {
+			min = ttl
+			ok = true
+		}
+	}
+	return min, ok
+}
+
+// cacheStats reports the running hit/miss counters for the response cache.
+func cacheStats() (hits, misses uint64) {
+	return atomic.LoadUint64(&cacheHits), atomic.LoadUint64(&cacheMisses)
+}
+
+func logCacheStats() {
+	hits, misses := cacheStats()
+	log.Printf("Forwarding cache: %d hits, %d misses", hits, misses)
+}
+
+// cacheStatsLogInterval controls how often the forwarding cache's hit/miss
+// counters are logged, when the response cache is enabled.
+const cacheStatsLogInterval = 1 * time.Minute
+
+// startCacheStatsLogger periodically logs cacheStats so cache effectiveness
+// is visible in the server's own logs without a separate metrics endpoint.
+// It's a no-op on ticks where the cache isn't enabled.
+func startCacheStatsLogger() {
+	ticker := time.NewTicker(cacheStatsLogInterval)
+	go func() {
+		for range ticker.C {
+			if respCache != nil {
+				logCacheStats()
+			}
+		}
+	}()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func aRecord(name string, ttl uint32) *dns.A {
+	return &dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+	}
+}
+
+func soaRecord(name string, minttl uint32) *dns.SOA {
+	return &dns.SOA{
+		Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: minttl},
+		Minttl: minttl,
+	}
+}
+
+func TestCacheTTLPositiveResponseUsesMinimumTTL(t *testing.T) {
+	cacheMinTTL, cacheMaxTTL = 0, 3600
+	resp := &dns.Msg{
+		Answer: []dns.RR{aRecord("host.example.com.", 300), aRecord("host.example.com.", 60)},
+	}
+	resp.Rcode = dns.RcodeSuccess
+
+	if got := cacheTTL(resp); got != 60 {
+		t.Errorf("cacheTTL = %d, want 60 (the smallest TTL in the answer section)", got)
+	}
+}
+
+func TestCacheTTLPositiveResponseWithExplicitZeroTTL(t *testing.T) {
+	cacheMinTTL, cacheMaxTTL = 0, 3600
+	resp := &dns.Msg{
+		Answer: []dns.RR{aRecord("host.example.com.", 300), aRecord("host.example.com.", 0), aRecord("host.example.com.", 600)},
+	}
+	resp.Rcode = dns.RcodeSuccess
+
+	if got := cacheTTL(resp); got != 0 {
+		t.Errorf("cacheTTL = %d, want 0 (a record with an explicit 0 TTL must not be masked by a later non-zero TTL)", got)
+	}
+}
+
+func TestCacheTTLNegativeResponseUsesSOAMinimum(t *testing.T) {
+	cacheMinTTL, cacheMaxTTL = 0, 3600
+	resp := &dns.Msg{
+		Ns: []dns.RR{soaRecord("example.com.", 120)},
+	}
+	resp.Rcode = dns.RcodeNameError
+
+	if got := cacheTTL(resp); got != 120 {
+		t.Errorf("cacheTTL(NXDOMAIN) = %d, want 120 (SOA MINIMUM)", got)
+	}
+}
+
+func TestCacheTTLNoDataUsesSOAMinimum(t *testing.T) {
+	cacheMinTTL, cacheMaxTTL = 0, 3600
+	resp := &dns.Msg{
+		Ns: []dns.RR{soaRecord("example.com.", 45)},
+	}
+	resp.Rcode = dns.RcodeSuccess // NODATA: success, no answer, SOA in authority
+
+	if got := cacheTTL(resp); got != 45 {
+		t.Errorf("cacheTTL(NODATA) = %d, want 45 (SOA MINIMUM)", got)
+	}
+}
+
+func TestCacheTTLClampedToMinAndMax(t *testing.T) {
+	cacheMinTTL, cacheMaxTTL = 30, 100
+	resp := &dns.Msg{Answer: []dns.RR{aRecord("host.example.com.", 5)}}
+	resp.Rcode = dns.RcodeSuccess
+	if got := cacheTTL(resp); got != 30 {
+		t.Errorf("cacheTTL below cacheMinTTL = %d, want clamped to 30", got)
+	}
+
+	resp = &dns.Msg{Answer: []dns.RR{aRecord("host.example.com.", 1000)}}
+	resp.Rcode = dns.RcodeSuccess
+	if got := cacheTTL(resp); got != 100 {
+		t.Errorf("cacheTTL above cacheMaxTTL = %d, want clamped to 100", got)
+	}
+	cacheMinTTL, cacheMaxTTL = 0, 3600
+}
+
+func TestDecrementTTLsCapsButIgnoresOPT(t *testing.T) {
+	msg := &dns.Msg{
+		Answer: []dns.RR{aRecord("host.example.com.", 300)},
+		Extra:  []dns.RR{&dns.OPT{Hdr: dns.RR_Header{Rrtype: dns.TypeOPT, Ttl: 999}}},
+	}
+
+	decrementTTLs(msg, 60)
+
+	if got := msg.Answer[0].Header().Ttl; got != 60 {
+		t.Errorf("answer TTL after decrementTTLs = %d, want capped to 60", got)
+	}
+	if got := msg.Extra[0].Header().Ttl; got != 999 {
+		t.Errorf("OPT TTL after decrementTTLs = %d, want untouched at 999", got)
+	}
+}
+
+func TestResponseCacheGetSetAndEviction(t *testing.T) {
+	c := newResponseCache(2)
+	keyA := cacheKey{qname: "a.example.com.", qtype: dns.TypeA}
+	keyB := cacheKey{qname: "b.example.com.", qtype: dns.TypeA}
+	keyC := cacheKey{qname: "c.example.com.", qtype: dns.TypeA}
+
+	c.set(keyA, &dns.Msg{Answer: []dns.RR{aRecord("a.example.com.", 300)}}, 300)
+	c.set(keyB, &dns.Msg{Answer: []dns.RR{aRecord("b.example.com.", 300)}}, 300)
+
+	if _, ok := c.get(keyA); !ok {
+		t.Fatal("expected a cache hit for keyA")
+	}
+
+	// Capacity is 2 and keyA was just moved to the front by the get above,
+	// so adding keyC should evict keyB (the least recently used).
+	c.set(keyC, &dns.Msg{Answer: []dns.RR{aRecord("c.example.com.", 300)}}, 300)
+
+	if _, ok := c.get(keyB); ok {
+		t.Error("expected keyB to have been evicted as least recently used")
+	}
+	if _, ok := c.get(keyA); !ok {
+		t.Error("expected keyA to still be cached")
+	}
+	if _, ok := c.get(keyC); !ok {
+		t.Error("expected keyC to be cached")
+	}
+}
+
+func TestResponseCacheGetExpiresEntry(t *testing.T) {
+	c := newResponseCache(10)
+	key := cacheKey{qname: "expired.example.com.", qtype: dns.TypeA}
+
+	c.set(key, &dns.Msg{Answer: []dns.RR{aRecord("expired.example.com.", 300)}}, 1)
+	elem := c.items[key]
+	elem.Value.(*cacheEntry).expires = time.Now().Add(-time.Second)
+
+	if _, ok := c.get(key); ok {
+		t.Error("expected get to report a miss for an expired entry")
+	}
+	if _, ok := c.items[key]; ok {
+		t.Error("expected the expired entry to be removed from the cache")
+	}
+}
@@ -6,9 +6,9 @@ import (
 	"log"
 	"net"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/miekg/dns"
 	"gopkg.in/ini.v1" // Import the ini package for reading .ini files
@@ -16,24 +16,36 @@ import (
 )
 
 type Record struct {
-	Name string `yaml:"name"`
-	Type string `yaml:"type"`
-	TTL  uint32 `yaml:"ttl"`
-	Data string `yaml:"data"`
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	TTL      uint32 `yaml:"ttl"`
+	Data     string `yaml:"data"`
+	Priority uint16 `yaml:"priority,omitempty"`
+	Weight   uint16 `yaml:"weight,omitempty"`
+	Port     uint16 `yaml:"port,omitempty"`
+	Target   string `yaml:"target,omitempty"`
+	Flag     uint8  `yaml:"flag,omitempty"`
+	Tag      string `yaml:"tag,omitempty"`
+	Mname    string `yaml:"mname,omitempty"`
+	Rname    string `yaml:"rname,omitempty"`
+	Serial   uint32 `yaml:"serial,omitempty"`
+	Refresh  uint32 `yaml:"refresh,omitempty"`
+	Retry    uint32 `yaml:"retry,omitempty"`
+	Expire   uint32 `yaml:"expire,omitempty"`
+	Minimum  uint32 `yaml:"minimum,omitempty"`
 }
 
 type Config struct {
 	Records []Record `yaml:"records"`
 }
 
-var dnsRecords map[string][]dns.RR
 var port string
 var forwarder string
 var queryLogging bool
 var queryLogFile string
 var queryLog *os.File
 var enableForwarding bool
-var zoneFileFormat string
+var settingsFilePath string
 
 func loadConfig(filename string) error {
 	cfg, err := ini.Load(filename)
@@ -41,74 +53,106 @@ func loadConfig(filename string) error {
 		return err
 	}
 
-	// Load zone file settings
-	zoneFile := cfg.Section("").Key("zone_file").String()
-	zoneFileFormat = cfg.Section("").Key("zone_file_format").String()
-
-	// Verify file extension matches the specified format
-	ext := filepath.Ext(zoneFile)
-	switch zoneFileFormat {
-	case "yaml":
-		if ext != ".yaml" && ext != ".yml" {
-			log.Fatalf("Zone file %s has extension %s, but format is specified as YAML", zoneFile, ext)
-			return fmt.Errorf("zone file extension does not match specified format")
-		}
-	case "csv":
-		if ext != ".csv" {
-			log.Fatalf("Zone file %s has extension %s, but format is specified as CSV", zoneFile, ext)
-			return fmt.Errorf("zone file extension does not match specified format")
-		}
-	}
-
-	// Load zone data with the correct file and format
-	if err := loadZoneData(zoneFile, zoneFileFormat); err != nil {
-		return err
-	}
+	settingsFilePath = filename
 
 	// Load port
 	port = cfg.Section("").Key("port").String()
 
-	// Load forwarder
+	// Load forwarder (used as the global fallback forwarder, and by the
+	// implicit zone synthesized when no [zone:<origin>] sections are defined)
 	forwarder = cfg.Section("").Key("forwarder").String()
 
+	// Load zones: one or more [zone:<origin>] sections, or a single implicit
+	// zone built from the legacy top-level zone_file/zone_file_format keys.
+	zones, err := loadZones(cfg)
+	if err != nil {
+		return err
+	}
+	zoneTable.Store(&zones)
+
 	// Load query logging settings
 	queryLogging = cfg.Section("").Key("query_logging").MustBool(false)
 	queryLogFile = cfg.Section("").Key("query_log_file").String()
+	logFormat = strings.ToLower(cfg.Section("").Key("log_format").MustString(logFormatText))
+	logSink = cfg.Section("").Key("log_sink").String()
+	if logSink == "" {
+		// Fall back to the legacy query_log_file setting.
+		logSink = queryLogFile
+	}
 
 	// Load enable forwarding setting
 	enableForwarding = cfg.Section("").Key("enable_forwarding").MustBool(true)
 
+	// Load forwarding response cache settings. A zero cache_size disables
+	// the cache entirely.
+	cacheSize = cfg.Section("").Key("cache_size").MustInt(0)
+	cacheMaxTTL = uint32(cfg.Section("").Key("cache_max_ttl").MustUint(3600))
+	cacheMinTTL = uint32(cfg.Section("").Key("cache_min_ttl").MustUint(0))
+	if cacheSize > 0 {
+		respCache = newResponseCache(cacheSize)
+	} else {
+		respCache = nil
+	}
+
+	// Load EDNS(0) settings
+	ednsUDPSize = uint16(cfg.Section("").Key("edns_udp_size").MustUint(1232))
+	nsid = cfg.Section("").Key("nsid").String()
+
+	// Load listener settings
+	listenUDP = cfg.Section("").Key("listen_udp").String()
+	listenTCP = cfg.Section("").Key("listen_tcp").String()
+	listenTLS = cfg.Section("").Key("listen_tls").String()
+	listenHTTPS = cfg.Section("").Key("listen_https").String()
+	listenQUIC = cfg.Section("").Key("listen_quic").String()
+	tlsCertFile = cfg.Section("").Key("tls_cert_file").String()
+	tlsKeyFile = cfg.Section("").Key("tls_key_file").String()
+	if listenUDP == "" && listenTCP == "" && port != "" {
+		// Fall back to the legacy single-port UDP listener for existing configs.
+		listenUDP = ":" + port
+	}
+
 	// Log settings
 	log.Printf("Configuration loaded:")
-	log.Printf("  Zone file: %s", zoneFile)
+	for _, zone := range zones {
+		log.Printf("  Zone: %s file=%s format=%s authoritative=%t forwarders=%v", zone.Origin, zone.File, zone.Format, zone.Authoritative, zone.Forwarders)
+	}
 	log.Printf("  Port: %s", port)
 	log.Printf("  Forwarder: %s", forwarder)
 	log.Printf("  Query logging: %t", queryLogging)
-	log.Printf("  Query log file: %s", queryLogFile)
+	log.Printf("  Log format: %s", logFormat)
+	log.Printf("  Log sink: %s", logSink)
 	log.Printf("  Enable forwarding: %t", enableForwarding)
-
-	// Open query log file if logging is enabled
+	log.Printf("  Cache size: %d", cacheSize)
+	log.Printf("  Cache max TTL: %d", cacheMaxTTL)
+	log.Printf("  Cache min TTL: %d", cacheMinTTL)
+	log.Printf("  EDNS UDP size: %d", ednsUDPSize)
+	log.Printf("  NSID: %q", nsid)
+	log.Printf("  Listen UDP: %q", listenUDP)
+	log.Printf("  Listen TCP: %q", listenTCP)
+	log.Printf("  Listen TLS (DoT): %q", listenTLS)
+	log.Printf("  Listen HTTPS (DoH): %q", listenHTTPS)
+	log.Printf("  Listen QUIC (DoQ): %q", listenQUIC)
+
+	// Open the query log sink if logging is enabled
 	if queryLogging {
-		var err error
-		queryLog, err = os.OpenFile(queryLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
-			return fmt.Errorf("failed to open query log file: %v", err)
+		if err := setupQueryLogging(); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func loadZoneData(filename, format string) error {
+func loadZoneData(filename, format string, defaultTTL uint32) (map[string][]dns.RR, error) {
 	log.Printf("Loading zone data from file: %s with format: %s", filename, format) // Debug log
 
 	file, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("error opening file %s: %w", filename, err)
+		return nil, fmt.Errorf("error opening file %s: %w", filename, err)
 	}
 	defer file.Close()
 
-	dnsRecords = make(map[string][]dns.RR)
+	records := make(map[string][]dns.RR)
 
 	switch format {
 	case "yaml":
@@ -116,51 +160,61 @@ func loadZoneData(filename, format string) error {
 		var config Config
 		err = decoder.Decode(&config)
 		if err != nil {
-			return fmt.Errorf("error decoding YAML file %s: %w", filename, err)
+			return nil, fmt.Errorf("error decoding YAML file %s: %w", filename, err)
 		}
 		for _, record := range config.Records {
-			addRecord(record)
+			if record.TTL == 0 {
+				record.TTL = defaultTTL
+			}
+			addRecord(records, record)
 		}
 	case "csv":
 		reader := csv.NewReader(file)
 		reader.TrimLeadingSpace = true
 		reader.LazyQuotes = true
 
-		records, err := reader.ReadAll()
+		rows, err := reader.ReadAll()
 		if err != nil {
-			return fmt.Errorf("error reading CSV file %s: %w", filename, err)
+			return nil, fmt.Errorf("error reading CSV file %s: %w", filename, err)
 		}
-		if len(records) <= 1 {
+		if len(rows) <= 1 {
 			log.Println("CSV file is empty or has only header")
-			return nil
+			return records, nil
 		}
 
-		// Skip header row
-		for i, record := range records[1:] {
-			if len(record) != 4 {
-				log.Printf("Invalid record format at line %d: %v", i+2, record) // i+2 because we skipped the header
-				continue
-			}
-			ttl, err := parseTTL(record[2])
+		// The header row drives which columns are present; this lets rows for
+		// different RR types carry only the fields that type needs instead of
+		// forcing every row into the same fixed 4-column shape.
+		columns := buildColumnIndex(rows[0])
+		for i, row := range rows[1:] {
+			recordData, err := recordFromCSVRow(row, columns)
 			if err != nil {
-				log.Printf("Invalid TTL value at line %d: %v, error: %v", i+2, record[2], err)
+				log.Printf("Invalid record format at line %d: %v", i+2, err) // i+2 because we skipped the header
 				continue
 			}
-			recordData := Record{
-				Name: record[0],
-				Type: record[1],
-				TTL:  ttl,
-				Data: record[3],
+			if recordData.TTL == 0 {
+				recordData.TTL = defaultTTL
 			}
-			addRecord(recordData)
+			addRecord(records, recordData)
+		}
+
+	case "bind":
+		zp := dns.NewZoneParser(file, "", filename)
+		zp.SetDefaultTTL(defaultTTL)
+		for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+			name := strings.ToLower(rr.Header().Name)
+			records[name] = append(records[name], rr)
+		}
+		if err := zp.Err(); err != nil {
+			return nil, fmt.Errorf("error parsing BIND zone file %s: %w", filename, err)
 		}
 
 	default:
-		return fmt.Errorf("unsupported zone file format: %s", format)
+		return nil, fmt.Errorf("unsupported zone file format: %s", format)
 	}
 
 	log.Printf("Zone file loaded successfully.")
-	return nil
+	return records, nil
 }
 
 func parseTTL(ttlStr string) (uint32, error) {
@@ -171,7 +225,7 @@ func parseTTL(ttlStr string) (uint32, error) {
 	return uint32(ttlInt), nil
 }
 
-func addRecord(record Record) {
+func addRecord(records map[string][]dns.RR, record Record) {
 	// Normalize the record name by adding a trailing dot if it's missing
 	if !strings.HasSuffix(record.Name, ".") {
 		record.Name += "."
@@ -199,70 +253,296 @@ func addRecord(record Record) {
 			},
 			Target: record.Data,
 		}
+	case "AAAA":
+		rr = &dns.AAAA{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeAAAA,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			AAAA: net.ParseIP(record.Data),
+		}
+	case "MX":
+		rr = &dns.MX{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeMX,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Preference: record.Priority,
+			Mx:         dns.Fqdn(record.Data),
+		}
+	case "TXT":
+		rr = &dns.TXT{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeTXT,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Txt: splitTXTChunks(record.Data),
+		}
+	case "NS":
+		rr = &dns.NS{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeNS,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Ns: dns.Fqdn(record.Data),
+		}
+	case "SOA":
+		rr = &dns.SOA{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeSOA,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Ns:      dns.Fqdn(record.Mname),
+			Mbox:    dns.Fqdn(record.Rname),
+			Serial:  record.Serial,
+			Refresh: record.Refresh,
+			Retry:   record.Retry,
+			Expire:  record.Expire,
+			Minttl:  record.Minimum,
+		}
+	case "SRV":
+		rr = &dns.SRV{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeSRV,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Priority: record.Priority,
+			Weight:   record.Weight,
+			Port:     record.Port,
+			Target:   dns.Fqdn(record.Target),
+		}
+	case "PTR":
+		rr = &dns.PTR{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypePTR,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Ptr: dns.Fqdn(record.Data),
+		}
+	case "CAA":
+		rr = &dns.CAA{
+			Hdr: dns.RR_Header{
+				Name:   record.Name,
+				Rrtype: dns.TypeCAA,
+				Class:  dns.ClassINET,
+				Ttl:    record.TTL,
+			},
+			Flag:  record.Flag,
+			Tag:   record.Tag,
+			Value: record.Data,
+		}
 	default:
 		log.Printf("Unsupported record type: %s", record.Type)
 		return
 	}
-	dnsRecords[strings.ToLower(record.Name)] = append(dnsRecords[strings.ToLower(record.Name)], rr)
+	records[strings.ToLower(record.Name)] = append(records[strings.ToLower(record.Name)], rr)
 	log.Printf("Loaded record: %s %s %d %s", record.Name, record.Type, record.TTL, record.Data)
 }
 
-func logQuery(query string, responseType string) {
-	if queryLogging && queryLog != nil {
-		logLine := fmt.Sprintf("Query: %s, Response: %s\n", query, responseType)
-		queryLog.WriteString(logLine)
+// forwardQuery exchanges r with forwarderAddr and, on success, writes the
+// upstream response back to w and logs it. It reports whether it forwarded
+// the query at all, so the caller knows whether to fall through to NXDOMAIN.
+// A configured respCache is consulted before the exchange and populated
+// after it, so repeated queries for the same (qname, qtype, qclass) don't
+// all hit the upstream resolver. Every response written here goes through
+// the same attachEDNS/truncateForUDP pass as the authoritative path, since
+// forwardQuery returns straight to the caller instead of falling through to
+// the end of handleRequest.
+func forwardQuery(w dns.ResponseWriter, m, r *dns.Msg, question dns.Question, forwarderAddr, socketFamily, socketProtocol string, queryBytes []byte, clientOpt *dns.OPT) bool {
+	if forwarderAddr == "" {
+		return false
+	}
+
+	key := cacheKeyFor(question)
+	if respCache != nil {
+		if cached, ok := respCache.get(key); ok {
+			cached.CopyTo(m)
+			m.SetReply(r)
+			attachEDNS(m, clientOpt)
+			truncateForUDP(w, m, clientOpt)
+			w.WriteMsg(m)
+			logMessage(queryLogEntry{
+				Timestamp:       time.Now(),
+				MessageType:     "RESOLVER_RESPONSE",
+				SocketFamily:    socketFamily,
+				SocketProtocol:  socketProtocol,
+				QueryName:       question.Name,
+				ResponseType:    "Cached response",
+				ResolverAddress: forwarderAddr,
+				QueryBytes:      queryBytes,
+			})
+			return true
+		}
+	}
+
+	c := new(dns.Client)
+	// r is forwarded unmodified, so any client OPT record - including the DO
+	// bit and an EDNS client subnet option - passes through to the upstream
+	// resolver as-is.
+	resp, _, err := c.Exchange(r, forwarderAddr+":53")
+	if err != nil {
+		log.Printf("Error forwarding request to %s: %v", forwarderAddr, err)
+		return false
+	}
+
+	if respCache != nil {
+		respCache.set(key, resp, cacheTTL(resp))
 	}
+
+	resp.CopyTo(m) // Copy the response to the message
+	m.SetReply(r)  // Ensure the message is a reply
+	attachEDNS(m, clientOpt)
+	truncateForUDP(w, m, clientOpt)
+	w.WriteMsg(m)
+
+	responseBytes, packErr := resp.Pack()
+	if packErr != nil {
+		log.Printf("Failed to pack forwarded response for logging: %v", packErr)
+	}
+	logMessage(queryLogEntry{
+		Timestamp:       time.Now(),
+		MessageType:     "RESOLVER_RESPONSE",
+		SocketFamily:    socketFamily,
+		SocketProtocol:  socketProtocol,
+		QueryName:       question.Name,
+		ResponseType:    "Forwarded response",
+		ResolverAddress: forwarderAddr,
+		QueryBytes:      queryBytes,
+		ResponseBytes:   responseBytes,
+	})
+	return true
 }
 
 func handleRequest(w dns.ResponseWriter, r *dns.Msg) {
 	m := new(dns.Msg)
 	m.SetReply(r)
 
+	// Detect the client's EDNS(0) OPT record, if any, so we can honor its
+	// advertised UDP size and DO bit and echo our own OPT back.
+	clientOpt := r.IsEdns0()
+
+	socketFamily, socketProtocol := socketInfo(w)
+	queryBytes, err := r.Pack()
+	if err != nil {
+		log.Printf("Failed to pack query for logging: %v", err)
+	}
+
+	// Locally-served and NXDOMAIN log entries are queued here and only
+	// logged once m is complete (after EDNS/truncation and WriteMsg), so
+	// ResponseBytes reflects what was actually sent instead of staying nil.
+	// Forwarded responses log themselves from inside forwardQuery, which
+	// already has the upstream's response bytes in hand.
+	var pendingLogs []queryLogEntry
+
 	for _, question := range r.Question {
 		queryName := question.Name
 		// Normalize the query name by adding a trailing dot if it's missing
 		if !strings.HasSuffix(queryName, ".") {
 			queryName += "."
 		}
-
 		queryName = strings.ToLower(queryName) // Convert query name to lowercase
 		log.Printf("Received query for: %s", question.Name)
 
-		records, ok := dnsRecords[queryName] // Use lowercase query name
-		if ok {
-			log.Printf("Found local records for %s", question.Name)
-			if len(records) > 0 {
-				log.Printf("Responding with local records")
-				m.Answer = append(m.Answer, records...)
-				logQuery(question.Name, "Authoritative response")
-			} else {
-				log.Printf("No records found for %s, but key exists", question.Name)
-			}
-		} else {
-			log.Printf("No local records found for %s", question.Name)
-			// If no records found, forward to the upstream DNS server if forwarding is enabled
-			if enableForwarding && forwarder != "" {
-				c := new(dns.Client)
-				resp, _, err := c.Exchange(r, forwarder+":53")
-				if err == nil {
-					// Forward the response from the upstream server
-					resp.CopyTo(m) // Copy the response to the message
-					m.SetReply(r)  // Ensure the message is a reply
-					w.WriteMsg(m)
-					logQuery(question.Name, "Forwarded response")
-					return
-				} else {
-					log.Printf("Error forwarding request: %v", err)
-				}
+		// Route to the most specific (longest-origin) zone that covers this
+		// question, falling back to the global forwarder only if no zone
+		// matches at all.
+		zone := findZone(queryName)
+		if zone == nil {
+			log.Printf("No zone configured for %s", question.Name)
+			if enableForwarding && forwardQuery(w, m, r, question, forwarder, socketFamily, socketProtocol, queryBytes, clientOpt) {
+				return
 			}
-			// If no records found and no forwarding occurred, respond with NXDOMAIN
 			m.SetRcode(r, dns.RcodeNameError)
-			logQuery(question.Name, "NXDOMAIN response")
+			pendingLogs = append(pendingLogs, queryLogEntry{
+				Timestamp:      time.Now(),
+				MessageType:    "CLIENT_RESPONSE",
+				SocketFamily:   socketFamily,
+				SocketProtocol: socketProtocol,
+				QueryName:      question.Name,
+				ResponseType:   "NXDOMAIN response",
+				QueryBytes:     queryBytes,
+			})
+			continue
+		}
+
+		var records []dns.RR
+		if zoneRecords := zone.records.Load(); zoneRecords != nil {
+			records = (*zoneRecords)[queryName]
+		}
+
+		if len(records) > 0 {
+			log.Printf("Found records for %s in zone %s", question.Name, zone.Origin)
+			m.Answer = append(m.Answer, records...)
+			if zone.Authoritative {
+				m.Authoritative = true
+			}
+			pendingLogs = append(pendingLogs, queryLogEntry{
+				Timestamp:      time.Now(),
+				MessageType:    "CLIENT_RESPONSE",
+				SocketFamily:   socketFamily,
+				SocketProtocol: socketProtocol,
+				QueryName:      question.Name,
+				ResponseType:   "Authoritative response",
+				QueryBytes:     queryBytes,
+			})
+			continue
 		}
+
+		log.Printf("No records for %s in zone %s", question.Name, zone.Origin)
+
+		// A stub zone (authoritative=false), or an authoritative zone with
+		// its own forwarder list, forwards to its zone-specific forwarder
+		// before falling back to the global one.
+		zoneForwarder := forwarder
+		if len(zone.Forwarders) > 0 {
+			zoneForwarder = zone.Forwarders[0]
+		} else if zone.Authoritative {
+			zoneForwarder = ""
+		}
+		if enableForwarding && forwardQuery(w, m, r, question, zoneForwarder, socketFamily, socketProtocol, queryBytes, clientOpt) {
+			return
+		}
+
+		m.SetRcode(r, dns.RcodeNameError)
+		pendingLogs = append(pendingLogs, queryLogEntry{
+			Timestamp:      time.Now(),
+			MessageType:    "CLIENT_RESPONSE",
+			SocketFamily:   socketFamily,
+			SocketProtocol: socketProtocol,
+			QueryName:      question.Name,
+			ResponseType:   "NXDOMAIN response",
+			QueryBytes:     queryBytes,
+		})
 	}
 
+	attachEDNS(m, clientOpt)
+	truncateForUDP(w, m, clientOpt)
+
 	// Send the response back to the client
 	w.WriteMsg(m)
+
+	responseBytes, packErr := m.Pack()
+	if packErr != nil {
+		log.Printf("Failed to pack response for logging: %v", packErr)
+	}
+	for _, entry := range pendingLogs {
+		entry.ResponseBytes = responseBytes
+		logMessage(entry)
+	}
 }
 
 func main() {
@@ -271,18 +551,23 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Set up the DNS handler for incoming requests
-	dns.HandleFunc(".", handleRequest)
-
-	// Create and start the DNS server
-	server := &dns.Server{Addr: fmt.Sprintf(":%s", port), Net: "udp"}
-	log.Printf("Starting DNS server on :%s\n", port)
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("Failed to start server: %s\n", err.Error())
+	// Reload settings.conf and every zone file on SIGHUP, and watch each
+	// zone's file for on-disk changes so edits are picked up without a
+	// restart.
+	setupSignalReload()
+	if table := zoneTable.Load(); table != nil {
+		watchZoneFiles(*table)
 	}
 
-	// Close the query log file if it was opened
-	if queryLog != nil {
-		queryLog.Close()
+	// Periodically log forwarding cache hit/miss counters, a no-op unless
+	// cache_size enables the response cache.
+	startCacheStatsLogger()
+
+	// Start every transport enabled in settings.conf (UDP, TCP, DoT, DoH,
+	// DoQ); each runs in its own goroutine against the shared handleRequest.
+	if err := startListeners(); err != nil {
+		log.Fatalf("Failed to start listeners: %v", err)
 	}
+
+	select {}
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/miekg/dns"
+)
+
+// doHMaxMsgSize bounds how much of a DoH request body we'll read, matching
+// the maximum size of a DNS message over TCP.
+const doHMaxMsgSize = 65535
+
+// runDoHListener serves DNS-over-HTTPS per RFC 8484, accepting both the GET
+// base64url ?dns= form and the POST application/dns-message form.
+func runDoHListener(addr string, tlsConfig *tls.Config) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dns-query", handleDoHQuery)
+
+	server := &http.Server{Addr: addr, Handler: mux, TLSConfig: tlsConfig}
+	log.Printf("Starting DoH listener on %s", addr)
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		log.Fatalf("Failed to start DoH listener on %s: %v", addr, err)
+	}
+}
+
+func handleDoHQuery(w http.ResponseWriter, req *http.Request) {
+	var raw []byte
+	var err error
+
+	switch req.Method {
+	case http.MethodGet:
+		encoded := req.URL.Query().Get("dns")
+		if encoded == "" {
+			http.Error(w, "missing dns parameter", http.StatusBadRequest)
+			return
+		}
+		raw, err = base64.RawURLEncoding.DecodeString(encoded)
+	case http.MethodPost:
+		if req.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		raw, err = io.ReadAll(io.LimitReader(req.Body, doHMaxMsgSize))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "malformed dns query", http.StatusBadRequest)
+		return
+	}
+
+	query := new(dns.Msg)
+	if err := query.Unpack(raw); err != nil {
+		http.Error(w, "malformed dns message", http.StatusBadRequest)
+		return
+	}
+
+	rw := &captureResponseWriter{remoteAddr: httpRemoteAddr(req)}
+	handleRequest(rw, query)
+	if rw.msg == nil {
+		http.Error(w, "no response generated", http.StatusInternalServerError)
+		return
+	}
+
+	packed, err := rw.msg.Pack()
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/dns-message")
+	w.Write(packed)
+}
+
+// httpRemoteAddr turns an http.Request's textual RemoteAddr into a net.Addr
+// so it can flow into dns.ResponseWriter.RemoteAddr unchanged.
+func httpRemoteAddr(req *http.Request) net.Addr {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return &net.TCPAddr{IP: net.ParseIP(host)}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+)
+
+// doqMaxMsgSize bounds how much of a DoQ stream we'll read for one query.
+const doqMaxMsgSize = 65535
+
+// runDoQListener serves DNS-over-QUIC per RFC 9250: each query arrives as
+// the complete contents of a client-initiated bidirectional stream, with no
+// length prefix, since QUIC's own stream framing delineates the message.
+func runDoQListener(addr string, tlsConfig *tls.Config) {
+	doqTLSConfig := tlsConfig.Clone()
+	doqTLSConfig.NextProtos = []string{"doq"}
+
+	listener, err := quic.ListenAddr(addr, doqTLSConfig, nil)
+	if err != nil {
+		log.Fatalf("Failed to start DoQ listener on %s: %v", addr, err)
+	}
+	log.Printf("Starting DoQ listener on %s", addr)
+
+	for {
+		conn, err := listener.Accept(context.Background())
+		if err != nil {
+			log.Printf("DoQ accept error: %v", err)
+			continue
+		}
+		go handleDoQConnection(conn)
+	}
+}
+
+func handleDoQConnection(conn quic.Connection) {
+	for {
+		stream, err := conn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		go handleDoQStream(conn, stream)
+	}
+}
+
+func handleDoQStream(conn quic.Connection, stream quic.Stream) {
+	defer stream.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(stream, doqMaxMsgSize))
+	if err != nil {
+		log.Printf("DoQ stream read error: %v", err)
+		return
+	}
+
+	query := new(dns.Msg)
+	if err := query.Unpack(raw); err != nil {
+		log.Printf("DoQ malformed message: %v", err)
+		return
+	}
+
+	rw := &captureResponseWriter{remoteAddr: conn.RemoteAddr()}
+	handleRequest(rw, query)
+	if rw.msg == nil {
+		return
+	}
+
+	packed, err := rw.msg.Pack()
+	if err != nil {
+		log.Printf("DoQ failed to encode response: %v", err)
+		return
+	}
+	if _, err := stream.Write(packed); err != nil {
+		log.Printf("DoQ stream write error: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/hex"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// ednsUDPSize is the UDP payload size we advertise to clients via EDNS(0),
+// configurable via the edns_udp_size setting (RFC 6891 recommends 1232 to
+// stay under common path MTUs).
+var ednsUDPSize uint16 = 1232
+
+// nsid, if set via the nsid setting, is returned to clients that request it
+// as an EDNS(0) NSID option so they can identify which server answered.
+var nsid string
+
+// attachEDNS appends an OPT record to the response whenever the client's
+// query included one, honoring the DNSSEC OK bit and advertising our own
+// UDP buffer size and NSID per RFC 6891. Any OPT record m already carries -
+// e.g. one copied in from an upstream's forwarded response - is stripped
+// first, since RFC 6891 forbids more than one OPT RR in a message.
+func attachEDNS(m *dns.Msg, clientOpt *dns.OPT) {
+	if clientOpt == nil {
+		return
+	}
+	stripOPT(m)
+
+	respOpt := &dns.OPT{
+		Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT},
+	}
+	respOpt.SetUDPSize(ednsUDPSize)
+	if clientOpt.Do() {
+		respOpt.SetDo()
+	}
+	if nsid != "" {
+		respOpt.Option = append(respOpt.Option, &dns.EDNS0_NSID{
+			Code: dns.EDNS0NSID,
+			Nsid: hex.EncodeToString([]byte(nsid)),
+		})
+	}
+	m.Extra = append(m.Extra, respOpt)
+}
+
+// stripOPT removes any existing OPT record from m.Extra in place.
+func stripOPT(m *dns.Msg) {
+	extra := m.Extra[:0]
+	for _, rr := range m.Extra {
+		if rr.Header().Rrtype != dns.TypeOPT {
+			extra = append(extra, rr)
+		}
+	}
+	m.Extra = extra
+}
+
+// effectiveUDPSize returns the buffer size the client advertised via
+// EDNS(0), falling back to the classic 512-byte limit for non-EDNS clients.
+func effectiveUDPSize(clientOpt *dns.OPT) int {
+	if clientOpt == nil {
+		return dns.MinMsgSize
+	}
+	size := int(clientOpt.UDPSize())
+	if size < dns.MinMsgSize {
+		return dns.MinMsgSize
+	}
+	return size
+}
+
+// truncateForUDP sets the TC bit and drops the answer/authority sections
+// when a UDP response would exceed the client's advertised buffer size, per
+// RFC 1035 section 4.2.1 and RFC 6891. The OPT record, if any, is preserved
+// so the client still learns our UDP size and NSID.
+func truncateForUDP(w dns.ResponseWriter, m *dns.Msg, clientOpt *dns.OPT) {
+	if _, isUDP := w.RemoteAddr().(*net.UDPAddr); !isUDP {
+		return
+	}
+	if m.Len() <= effectiveUDPSize(clientOpt) {
+		return
+	}
+
+	opt := m.IsEdns0()
+	m.Truncated = true
+	m.Answer = nil
+	m.Ns = nil
+	m.Extra = nil
+	if opt != nil {
+		m.Extra = append(m.Extra, opt)
+	}
+}
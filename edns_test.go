@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestAttachEDNSReplacesExistingOPT(t *testing.T) {
+	upstreamOpt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	upstreamOpt.SetUDPSize(4096)
+
+	m := &dns.Msg{Extra: []dns.RR{upstreamOpt}}
+	clientOpt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+
+	attachEDNS(m, clientOpt)
+
+	var optCount int
+	for _, rr := range m.Extra {
+		if rr.Header().Rrtype == dns.TypeOPT {
+			optCount++
+		}
+	}
+	if optCount != 1 {
+		t.Fatalf("message has %d OPT records after attachEDNS, want exactly 1 (RFC 6891)", optCount)
+	}
+	if got := m.Extra[len(m.Extra)-1].(*dns.OPT).UDPSize(); got != ednsUDPSize {
+		t.Errorf("surviving OPT advertises UDP size %d, want our own %d, not the upstream's", got, ednsUDPSize)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	listenUDP   string
+	listenTCP   string
+	listenTLS   string
+	listenHTTPS string
+	listenQUIC  string
+	tlsCertFile string
+	tlsKeyFile  string
+)
+
+// startListeners brings up every transport enabled in settings.conf, each in
+// its own goroutine, all dispatching to the shared handleRequest. It mirrors
+// the DoT/DoH/DoQ transport matrix awl implements client-side, but here on
+// the server.
+func startListeners() error {
+	dns.HandleFunc(".", handleRequest)
+
+	started := false
+
+	if listenUDP != "" {
+		started = true
+		go runDNSServer(&dns.Server{Addr: listenUDP, Net: "udp"})
+	}
+	if listenTCP != "" {
+		started = true
+		go runDNSServer(&dns.Server{Addr: listenTCP, Net: "tcp"})
+	}
+	if listenTLS != "" {
+		tlsConfig, err := loadTLSConfig()
+		if err != nil {
+			return fmt.Errorf("DoT listener: %w", err)
+		}
+		started = true
+		go runDNSServer(&dns.Server{Addr: listenTLS, Net: "tcp-tls", TLSConfig: tlsConfig})
+	}
+	if listenHTTPS != "" {
+		tlsConfig, err := loadTLSConfig()
+		if err != nil {
+			return fmt.Errorf("DoH listener: %w", err)
+		}
+		started = true
+		go runDoHListener(listenHTTPS, tlsConfig)
+	}
+	if listenQUIC != "" {
+		tlsConfig, err := loadTLSConfig()
+		if err != nil {
+			return fmt.Errorf("DoQ listener: %w", err)
+		}
+		started = true
+		go runDoQListener(listenQUIC, tlsConfig)
+	}
+
+	if !started {
+		return fmt.Errorf("no listeners configured: set at least one of listen_udp, listen_tcp, listen_tls, listen_https, listen_quic")
+	}
+	return nil
+}
+
+func loadTLSConfig() (*tls.Config, error) {
+	if tlsCertFile == "" || tlsKeyFile == "" {
+		return nil, fmt.Errorf("tls_cert_file and tls_key_file must be set for encrypted listeners")
+	}
+	cert, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading TLS certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+func runDNSServer(server *dns.Server) {
+	log.Printf("Starting DNS server on %s (%s)", server.Addr, server.Net)
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("Failed to start %s listener on %s: %v", server.Net, server.Addr, err)
+	}
+}
+
+// captureResponseWriter implements dns.ResponseWriter for transports, like
+// DoH and DoQ, that hand handleRequest a request/response pair rather than a
+// live packet connection: it just captures the *dns.Msg that gets written.
+type captureResponseWriter struct {
+	remoteAddr net.Addr
+	msg        *dns.Msg
+}
+
+func (w *captureResponseWriter) LocalAddr() net.Addr  { return &net.TCPAddr{} }
+func (w *captureResponseWriter) RemoteAddr() net.Addr { return w.remoteAddr }
+
+func (w *captureResponseWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+func (w *captureResponseWriter) Write(b []byte) (int, error) {
+	m := new(dns.Msg)
+	if err := m.Unpack(b); err != nil {
+		return 0, err
+	}
+	w.msg = m
+	return len(b), nil
+}
+
+func (w *captureResponseWriter) Close() error        { return nil }
+func (w *captureResponseWriter) TsigStatus() error   { return nil }
+func (w *captureResponseWriter) TsigTimersOnly(bool) {}
+func (w *captureResponseWriter) Hijack()             {}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	dnstap "github.com/dnstap/golang-dnstap"
+	"github.com/miekg/dns"
+)
+
+// Supported log_format values.
+const (
+	logFormatText   = "text"
+	logFormatJSON   = "json"
+	logFormatDnstap = "dnstap"
+)
+
+var (
+	logFormat    string
+	logSink      string
+	dnstapWriter *dnstap.Encoder
+)
+
+// queryLogEntry captures everything a log sink might want about one
+// query/response exchange, independent of the output format it ends up in.
+type queryLogEntry struct {
+	Timestamp       time.Time
+	MessageType     string // CLIENT_QUERY, CLIENT_RESPONSE, RESOLVER_QUERY, RESOLVER_RESPONSE
+	SocketFamily    string // INET, INET6
+	SocketProtocol  string // UDP, TCP
+	QueryName       string
+	ResponseType    string
+	ResolverAddress string // upstream forwarder identity, set only when forwarding
+	QueryBytes      []byte
+	ResponseBytes   []byte
+}
+
+// setupQueryLogging opens the configured log_sink for the configured
+// log_format: a dnstap framestream connection for "dnstap", or a plain file
+// for "text"/"json".
+func setupQueryLogging() error {
+	if logSink == "" {
+		return fmt.Errorf("query logging is enabled but log_sink is empty")
+	}
+
+	if logFormat == logFormatDnstap {
+		conn, err := openDnstapSink(logSink)
+		if err != nil {
+			return fmt.Errorf("failed to open dnstap sink %s: %w", logSink, err)
+		}
+		fsw, err := dnstap.NewWriter(conn, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start dnstap framestream on %s: %w", logSink, err)
+		}
+		dnstapWriter = dnstap.NewEncoder(fsw)
+		return nil
+	}
+
+	f, err := os.OpenFile(logSink, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open query log sink %s: %w", logSink, err)
+	}
+	queryLog = f
+	return nil
+}
+
+// openDnstapSink dials a Unix socket for a "unix://" sink, otherwise treats
+// the sink as a plain file path.
+func openDnstapSink(sink string) (io.WriteCloser, error) {
+	if path, ok := strings.CutPrefix(sink, "unix://"); ok {
+		return net.Dial("unix", path)
+	}
+	return os.OpenFile(sink, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
+
+// logMessage records a query/response exchange in whichever format
+// log_format selects.
+func logMessage(entry queryLogEntry) {
+	if !queryLogging {
+		return
+	}
+	switch logFormat {
+	case logFormatJSON:
+		logJSON(entry)
+	case logFormatDnstap:
+		logDnstap(entry)
+	default:
+		logText(entry)
+	}
+}
+
+func logText(entry queryLogEntry) {
+	if queryLog == nil {
+		return
+	}
+	line := fmt.Sprintf("Query: %s, Response: %s\n", entry.QueryName, entry.ResponseType)
+	if _, err := queryLog.WriteString(line); err != nil {
+		log.Printf("Failed to write query log line: %v", err)
+	}
+}
+
+func logJSON(entry queryLogEntry) {
+	if queryLog == nil {
+		return
+	}
+	record := struct {
+		Timestamp       string `json:"timestamp"`
+		MessageType     string `json:"message_type"`
+		SocketFamily    string `json:"socket_family"`
+		SocketProtocol  string `json:"socket_protocol"`
+		QueryName       string `json:"query_name"`
+		ResponseType    string `json:"response_type"`
+		ResolverAddress string `json:"resolver_address,omitempty"`
+	}{
+		Timestamp:       entry.Timestamp.Format(time.RFC3339Nano),
+		MessageType:     entry.MessageType,
+		SocketFamily:    entry.SocketFamily,
+		SocketProtocol:  entry.SocketProtocol,
+		QueryName:       entry.QueryName,
+		ResponseType:    entry.ResponseType,
+		ResolverAddress: entry.ResolverAddress,
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("Failed to marshal query log entry: %v", err)
+		return
+	}
+	if _, err := queryLog.Write(append(line, '\n')); err != nil {
+		log.Printf("Failed to write query log line: %v", err)
+	}
+}
+
+func logDnstap(entry queryLogEntry) {
+	if dnstapWriter == nil {
+		return
+	}
+
+	frame := &dnstap.Dnstap{
+		Type:    dnstap.Dnstap_MESSAGE.Enum(),
+		Message: buildDnstapMessage(entry),
+	}
+	if err := dnstapWriter.Encode(frame); err != nil {
+		log.Printf("Failed to write dnstap frame: %v", err)
+	}
+}
+
+func buildDnstapMessage(entry queryLogEntry) *dnstap.Message {
+	msgType := dnstap.Message_CLIENT_QUERY
+	switch entry.MessageType {
+	case "CLIENT_RESPONSE":
+		msgType = dnstap.Message_CLIENT_RESPONSE
+	case "RESOLVER_QUERY":
+		msgType = dnstap.Message_RESOLVER_QUERY
+	case "RESOLVER_RESPONSE":
+		msgType = dnstap.Message_RESOLVER_RESPONSE
+	}
+
+	family := dnstap.SocketFamily_INET
+	if entry.SocketFamily == "INET6" {
+		family = dnstap.SocketFamily_INET6
+	}
+	socketProto := dnstap.SocketProtocol_UDP
+	if entry.SocketProtocol == "TCP" {
+		socketProto = dnstap.SocketProtocol_TCP
+	}
+
+	sec := uint64(entry.Timestamp.Unix())
+	nsec := uint32(entry.Timestamp.Nanosecond())
+
+	msg := &dnstap.Message{
+		Type:           msgType.Enum(),
+		SocketFamily:   family.Enum(),
+		SocketProtocol: socketProto.Enum(),
+	}
+	if len(entry.QueryBytes) > 0 {
+		msg.QueryMessage = entry.QueryBytes
+		msg.QueryTimeSec = &sec
+		msg.QueryTimeNsec = &nsec
+	}
+	if len(entry.ResponseBytes) > 0 {
+		msg.ResponseMessage = entry.ResponseBytes
+		msg.ResponseTimeSec = &sec
+		msg.ResponseTimeNsec = &nsec
+	}
+	if entry.ResolverAddress != "" {
+		if ip := net.ParseIP(entry.ResolverAddress); ip != nil {
+			msg.ResponseAddress = []byte(ip)
+		}
+	}
+	return msg
+}
+
+// socketInfo reports the address family and transport protocol a query
+// arrived on, for inclusion in log entries.
+func socketInfo(w dns.ResponseWriter) (family, protocol string) {
+	switch addr := w.RemoteAddr().(type) {
+	case *net.UDPAddr:
+		return ipFamily(addr.IP), "UDP"
+	case *net.TCPAddr:
+		return ipFamily(addr.IP), "TCP"
+	default:
+		return "INET", "UDP"
+	}
+}
+
+func ipFamily(ip net.IP) string {
+	if ip.To4() != nil {
+		return "INET"
+	}
+	return "INET6"
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// maxTXTChunkLen is the largest number of bytes a single TXT character-string
+// can hold, per RFC 1035 section 3.3.14.
+const maxTXTChunkLen = 255
+
+// buildColumnIndex maps a CSV header row to the column index for each known
+// field name, so data rows can carry only the columns their RR type needs.
+func buildColumnIndex(header []string) map[string]int {
+	idx := make(map[string]int, len(header))
+	for i, col := range header {
+		idx[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	return idx
+}
+
+func csvField(row []string, idx map[string]int, col string) string {
+	i, ok := idx[col]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func csvUint(row []string, idx map[string]int, col string, bits int) uint64 {
+	v := csvField(row, idx, col)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, bits)
+	if err != nil {
+		log.Printf("Invalid %s value %q: %v", col, v, err)
+		return 0
+	}
+	return n
+}
+
+// recordFromCSVRow builds a Record from a data row using the column layout
+// discovered in the header, rather than assuming a fixed name/type/ttl/data
+// shape. Columns irrelevant to a given RR type are simply absent or blank.
+func recordFromCSVRow(row []string, idx map[string]int) (Record, error) {
+	typ := strings.ToUpper(csvField(row, idx, "type"))
+	if typ == "" {
+		return Record{}, fmt.Errorf("missing type column: %v", row)
+	}
+
+	ttl, err := parseTTL(csvField(row, idx, "ttl"))
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid ttl: %w", err)
+	}
+
+	record := Record{
+		Name:     csvField(row, idx, "name"),
+		Type:     typ,
+		TTL:      ttl,
+		Data:     csvField(row, idx, "data"),
+		Target:   csvField(row, idx, "target"),
+		Tag:      csvField(row, idx, "tag"),
+		Mname:    csvField(row, idx, "mname"),
+		Rname:    csvField(row, idx, "rname"),
+		Priority: uint16(csvUint(row, idx, "priority", 16)),
+		Weight:   uint16(csvUint(row, idx, "weight", 16)),
+		Port:     uint16(csvUint(row, idx, "port", 16)),
+		Flag:     uint8(csvUint(row, idx, "flag", 8)),
+		Serial:   uint32(csvUint(row, idx, "serial", 32)),
+		Refresh:  uint32(csvUint(row, idx, "refresh", 32)),
+		Retry:    uint32(csvUint(row, idx, "retry", 32)),
+		Expire:   uint32(csvUint(row, idx, "expire", 32)),
+		Minimum:  uint32(csvUint(row, idx, "minimum", 32)),
+	}
+	if record.Name == "" {
+		return Record{}, fmt.Errorf("missing name column: %v", row)
+	}
+	return record, nil
+}
+
+// splitTXTChunks breaks TXT record data into the multiple character-strings
+// a TXT RR is made of, since a single string is capped at 255 bytes.
+func splitTXTChunks(data string) []string {
+	if len(data) <= maxTXTChunkLen {
+		return []string{data}
+	}
+	var chunks []string
+	for len(data) > 0 {
+		n := maxTXTChunkLen
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return chunks
+}
+
+// forwardRecord is an A/AAAA record seen while scanning zones for auto-PTR
+// synthesis.
+type forwardRecord struct {
+	ip   net.IP
+	ttl  uint32
+	name string
+}
+
+// generateAutoPTRForZones synthesizes PTR records for every A/AAAA record
+// across all configured zones and stores each one into whichever zone's
+// origin actually covers the reverse name, not the zone the forward record
+// came from. This matters once forward and reverse data live in separate
+// zone sections (e.g. "internal.corp." vs "10.in-addr.arpa."): a client
+// querying the reverse zone needs to find the PTR there, not in the zone
+// that happened to define the A record.
+//
+// The whole synthesized set is recomputed on every call (driven by each
+// zone's current synthesizedPTR marker) rather than only ever adding PTRs,
+// so that a hot reload which removes an A/AAAA record also drops the PTR it
+// previously generated, instead of leaving it behind forever.
+func generateAutoPTRForZones(zones []*Zone) {
+	var forward []forwardRecord
+	for _, zone := range zones {
+		recs := zone.records.Load()
+		if recs == nil {
+			continue
+		}
+		for _, rrs := range *recs {
+			for _, rr := range rrs {
+				switch v := rr.(type) {
+				case *dns.A:
+					forward = append(forward, forwardRecord{v.A, v.Hdr.Ttl, v.Hdr.Name})
+				case *dns.AAAA:
+					forward = append(forward, forwardRecord{v.AAAA, v.Hdr.Ttl, v.Hdr.Name})
+				}
+			}
+		}
+	}
+
+	synthesized := make(map[*Zone]map[string][]dns.RR)
+	for _, fr := range forward {
+		if fr.ip == nil {
+			continue
+		}
+		reverseName, err := dns.ReverseAddr(fr.ip.String())
+		if err != nil {
+			continue
+		}
+		target := zoneForName(zones, reverseName)
+		if target == nil || !target.Authoritative {
+			continue // no zone covers this reverse name, or it's stub-forwarded
+		}
+		key := strings.ToLower(reverseName)
+		if explicitPTRExists(target, key) {
+			continue // an explicit PTR record already covers this address
+		}
+		if _, exists := synthesized[target][key]; exists {
+			continue // another forward record already synthesized this PTR
+		}
+		if synthesized[target] == nil {
+			synthesized[target] = make(map[string][]dns.RR)
+		}
+		synthesized[target][key] = []dns.RR{&dns.PTR{
+			Hdr: dns.RR_Header{
+				Name:   reverseName,
+				Rrtype: dns.TypePTR,
+				Class:  dns.ClassINET,
+				Ttl:    fr.ttl,
+			},
+			Ptr: fr.name,
+		}}
+	}
+
+	for _, zone := range zones {
+		add := synthesized[zone]
+
+		recs := zone.records.Load()
+		merged := make(map[string][]dns.RR, len(add))
+		if recs != nil {
+			for k, v := range *recs {
+				merged[k] = v
+			}
+		}
+
+		// Drop every key this function synthesized last time that isn't
+		// being re-synthesized this round - its source record is gone.
+		if prev := zone.synthesizedPTR.Load(); prev != nil {
+			for key := range *prev {
+				if _, stillSynthesized := add[key]; !stillSynthesized {
+					delete(merged, key)
+				}
+			}
+		}
+
+		newSynthesized := make(map[string]bool, len(add))
+		for key, rrs := range add {
+			merged[key] = rrs
+			newSynthesized[key] = true
+		}
+
+		zone.records.Store(&merged)
+		zone.synthesizedPTR.Store(&newSynthesized)
+	}
+}
+
+// explicitPTRExists reports whether zone's record set already has an entry
+// for key that wasn't itself synthesized by a previous auto-PTR pass.
+func explicitPTRExists(zone *Zone, key string) bool {
+	recs := zone.records.Load()
+	if recs == nil {
+		return false
+	}
+	if _, exists := (*recs)[key]; !exists {
+		return false
+	}
+	prev := zone.synthesizedPTR.Load()
+	return prev == nil || !(*prev)[key]
+}
+
+// zoneForName returns the most specific zone - the one with the longest
+// origin - whose origin is an ancestor of, or equal to, name. Unlike
+// findZone, it searches the given slice directly rather than the live
+// zoneTable, so it can be used while building the zone table itself.
+func zoneForName(zones []*Zone, name string) *Zone {
+	var best *Zone
+	for _, zone := range zones {
+		if !dns.IsSubDomain(zone.Origin, name) {
+			continue
+		}
+		if best == nil || len(zone.Origin) > len(best.Origin) {
+			best = zone
+		}
+	}
+	return best
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordFromCSVRowVariableColumns(t *testing.T) {
+	idx := buildColumnIndex([]string{"name", "type", "ttl", "priority", "target"})
+
+	record, err := recordFromCSVRow([]string{"mail.example.com", "MX", "300", "10", "mx1.example.com"}, idx)
+	if err != nil {
+		t.Fatalf("recordFromCSVRow returned error: %v", err)
+	}
+
+	want := Record{
+		Name:     "mail.example.com",
+		Type:     "MX",
+		TTL:      300,
+		Priority: 10,
+		Target:   "mx1.example.com",
+	}
+	if record != want {
+		t.Errorf("recordFromCSVRow = %+v, want %+v", record, want)
+	}
+}
+
+func TestRecordFromCSVRowMissingType(t *testing.T) {
+	idx := buildColumnIndex([]string{"name", "data"})
+	if _, err := recordFromCSVRow([]string{"host.example.com", "1.2.3.4"}, idx); err == nil {
+		t.Fatal("expected an error for a row with no type column, got nil")
+	}
+}
+
+func TestRecordFromCSVRowMissingName(t *testing.T) {
+	idx := buildColumnIndex([]string{"type", "data"})
+	if _, err := recordFromCSVRow([]string{"A", "1.2.3.4"}, idx); err == nil {
+		t.Fatal("expected an error for a row with no name column, got nil")
+	}
+}
+
+func TestSplitTXTChunksShortString(t *testing.T) {
+	got := splitTXTChunks("short value")
+	want := []string{"short value"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitTXTChunks = %v, want %v", got, want)
+	}
+}
+
+func TestSplitTXTChunksLongString(t *testing.T) {
+	data := strings.Repeat("a", 300)
+	chunks := splitTXTChunks(data)
+
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks for a 300-byte string, got %d", len(chunks))
+	}
+	if len(chunks[0]) != maxTXTChunkLen {
+		t.Errorf("first chunk length = %d, want %d", len(chunks[0]), maxTXTChunkLen)
+	}
+	if got := strings.Join(chunks, ""); got != data {
+		t.Errorf("rejoined chunks = %q, want %q", got, data)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/miekg/dns"
+)
+
+// setupSignalReload installs a SIGHUP handler that reloads settings.conf and
+// the zone file in place, without restarting the server.
+func setupSignalReload() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			log.Printf("Received SIGHUP, reloading configuration")
+			if err := loadConfig(settingsFilePath); err != nil {
+				log.Printf("Reload failed: %v", err)
+			}
+		}
+	}()
+}
+
+// watchZoneFiles starts a file watcher for every zone's file. Each watcher
+// resolves its zone by origin at reload time, rather than closing over the
+// *Zone pointer, so a zone rebuilt by a settings.conf reload still gets its
+// on-disk changes picked up without restarting the watcher.
+func watchZoneFiles(zones []*Zone) {
+	for _, zone := range zones {
+		watchZoneFile(zone.Origin, zone.File)
+	}
+}
+
+// watchZoneFile watches the directory containing a zone file and reloads
+// that zone whenever the file itself is written or replaced. The directory
+// is watched rather than the file directly so that editors which write via a
+// rename (write to a temp file, then rename over the original) are handled.
+func watchZoneFile(origin, path string) {
+	if path == "" {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Failed to create zone file watcher for %s: %v", origin, err)
+		return
+	}
+
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		log.Printf("Failed to watch zone file directory %s: %v", dir, err)
+		watcher.Close()
+		return
+	}
+
+	target := filepath.Clean(path)
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != target {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				log.Printf("Zone file %s for %s changed on disk, reloading", path, origin)
+				if err := reloadZoneByOrigin(origin); err != nil {
+					log.Printf("Zone %s reload failed: %v", origin, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Zone file watcher error for %s: %v", origin, err)
+			}
+		}
+	}()
+}
+
+// reloadZoneByOrigin re-runs loadZoneData for the zone currently configured
+// at origin and atomically swaps its record set so in-flight queries in
+// handleRequest never see a partially-loaded map, then logs a summary of
+// what changed.
+func reloadZoneByOrigin(origin string) error {
+	zone := findZoneByOrigin(origin)
+	if zone == nil {
+		return fmt.Errorf("zone %s is no longer configured", origin)
+	}
+
+	newRecords, err := loadZoneData(zone.File, zone.Format, zone.DefaultTTL)
+	if err != nil {
+		return err
+	}
+
+	var oldRecords map[string][]dns.RR
+	if oldPtr := zone.records.Load(); oldPtr != nil {
+		oldRecords = *oldPtr
+	}
+
+	added, removed, changed := diffRecords(oldRecords, newRecords)
+	zone.records.Store(&newRecords)
+
+	// Re-synthesize auto-PTR across every configured zone, not just this
+	// one: a changed A/AAAA record here may need its PTR added to, or
+	// removed from, a separate reverse zone.
+	if table := zoneTable.Load(); table != nil {
+		generateAutoPTRForZones(*table)
+	}
+
+	log.Printf("Zone %s reloaded: %d added, %d removed, %d changed", origin, added, removed, changed)
+	return nil
+}
+
+// diffRecords compares two record sets keyed by name and reports how many
+// names were added, removed, or had their record set change.
+func diffRecords(old, new map[string][]dns.RR) (added, removed, changed int) {
+	for name, newRRs := range new {
+		oldRRs, ok := old[name]
+		if !ok {
+			added++
+			continue
+		}
+		if !recordSetEqual(oldRRs, newRRs) {
+			changed++
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			removed++
+		}
+	}
+	return added, removed, changed
+}
+
+// recordSetEqual reports whether two RR sets for the same name contain the
+// same records, ignoring order.
+func recordSetEqual(a, b []dns.RR) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := make([]string, len(a))
+	bs := make([]string, len(b))
+	for i, rr := range a {
+		as[i] = rr.String()
+	}
+	for i, rr := range b {
+		bs[i] = rr.String()
+	}
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
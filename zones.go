@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/miekg/dns"
+	"gopkg.in/ini.v1"
+)
+
+// zoneSectionPrefix marks an ini section as a zone definition, e.g.
+// "[zone:internal.corp.]".
+const zoneSectionPrefix = "zone:"
+
+// Zone binds one zone file to an origin, with its own format, default TTL,
+// authoritative-vs-stub mode, and optional per-zone forwarders. This lets a
+// single server be authoritative for some origins while stub-forwarding
+// others upstream, instead of sharing one flat record set and one forwarder.
+type Zone struct {
+	Origin        string
+	File          string
+	Format        string // yaml, csv, or bind
+	DefaultTTL    uint32
+	Authoritative bool
+	Forwarders    []string
+
+	records atomic.Pointer[map[string][]dns.RR]
+
+	// synthesizedPTR tracks which keys in records hold a PTR auto-generated
+	// by the last generateAutoPTRForZones pass, as opposed to one parsed
+	// from the zone file. This lets a later pass - triggered by a hot
+	// reload - tell a stale synthesized PTR (its source A/AAAA record is
+	// gone) apart from an explicit one, which must never be touched.
+	synthesizedPTR atomic.Pointer[map[string]bool]
+}
+
+// zoneTable holds the zones currently in effect. It's swapped atomically on
+// reload so in-flight queries in handleRequest never see a half-built set.
+var zoneTable atomic.Pointer[[]*Zone]
+
+// loadZones reads every [zone:<origin>] section from settings.conf and loads
+// each one's zone file. If no zone sections are present, it falls back to a
+// single authoritative zone rooted at "." built from the legacy top-level
+// zone_file/zone_file_format settings, so existing configs keep working.
+func loadZones(cfg *ini.File) ([]*Zone, error) {
+	var zones []*Zone
+
+	for _, section := range cfg.Sections() {
+		name := section.Name()
+		if !strings.HasPrefix(name, zoneSectionPrefix) {
+			continue
+		}
+
+		origin := dns.Fqdn(strings.TrimPrefix(name, zoneSectionPrefix))
+		zone := &Zone{
+			Origin:        origin,
+			File:          section.Key("file").String(),
+			Format:        section.Key("format").String(),
+			DefaultTTL:    uint32(section.Key("ttl").MustUint(300)),
+			Authoritative: section.Key("authoritative").MustBool(true),
+		}
+		if fwd := section.Key("forwarder").String(); fwd != "" {
+			for _, addr := range strings.Split(fwd, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					zone.Forwarders = append(zone.Forwarders, addr)
+				}
+			}
+		}
+		zones = append(zones, zone)
+	}
+
+	if len(zones) == 0 {
+		zoneFile := cfg.Section("").Key("zone_file").String()
+		if zoneFile == "" {
+			return nil, fmt.Errorf("no zones configured: add a [zone:<origin>] section or a top-level zone_file")
+		}
+		zone := &Zone{
+			Origin:        ".",
+			File:          zoneFile,
+			Format:        cfg.Section("").Key("zone_file_format").String(),
+			Authoritative: true,
+		}
+		if forwarder := cfg.Section("").Key("forwarder").String(); forwarder != "" {
+			zone.Forwarders = []string{forwarder}
+		}
+		zones = append(zones, zone)
+	}
+
+	for _, zone := range zones {
+		if err := loadZoneRecords(zone); err != nil {
+			return nil, fmt.Errorf("zone %s: %w", zone.Origin, err)
+		}
+	}
+
+	// Auto-PTR synthesis needs every zone's records loaded first so it can
+	// route each synthesized PTR to whichever zone's origin actually covers
+	// the reverse name, rather than the zone the forward record came from.
+	generateAutoPTRForZones(zones)
+
+	return zones, nil
+}
+
+// loadZoneRecords (re)loads one zone's file into its own record set.
+func loadZoneRecords(zone *Zone) error {
+	records, err := loadZoneData(zone.File, zone.Format, zone.DefaultTTL)
+	if err != nil {
+		return err
+	}
+	zone.records.Store(&records)
+	return nil
+}
+
+// findZone returns the most specific zone - the one with the longest origin
+// - whose origin is an ancestor of, or equal to, name. It returns nil if no
+// configured zone covers name at all.
+func findZone(name string) *Zone {
+	table := zoneTable.Load()
+	if table == nil {
+		return nil
+	}
+
+	var best *Zone
+	for _, zone := range *table {
+		if !dns.IsSubDomain(zone.Origin, name) {
+			continue
+		}
+		if best == nil || len(zone.Origin) > len(best.Origin) {
+			best = zone
+		}
+	}
+	return best
+}
+
+// findZoneByOrigin looks up a zone by its exact origin in the current zone
+// table, used by the zone file watcher so a reload always targets whatever
+// zone is currently configured for that origin rather than a stale pointer.
+func findZoneByOrigin(origin string) *Zone {
+	table := zoneTable.Load()
+	if table == nil {
+		return nil
+	}
+	for _, zone := range *table {
+		if zone.Origin == origin {
+			return zone
+		}
+	}
+	return nil
+}
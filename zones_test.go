@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func newTestZone(origin string, authoritative bool, records map[string][]dns.RR) *Zone {
+	zone := &Zone{Origin: origin, Authoritative: authoritative}
+	zone.records.Store(&records)
+	return zone
+}
+
+// TestGenerateAutoPTRForZonesCrossZone reproduces a forward zone
+// ("internal.corp.") and a separate reverse zone ("10.in-addr.arpa.") and
+// checks that a PTR synthesized from an A record in the forward zone is
+// stored in the reverse zone, since that's the zone findZone will actually
+// route a matching PTR query to.
+func TestGenerateAutoPTRForZonesCrossZone(t *testing.T) {
+	forwardRecords := map[string][]dns.RR{
+		"host.internal.corp.": {&dns.A{
+			Hdr: dns.RR_Header{Name: "host.internal.corp.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   mustParseIP("10.0.0.1"),
+		}},
+	}
+	reverseRecords := map[string][]dns.RR{}
+
+	forwardZone := newTestZone("internal.corp.", true, forwardRecords)
+	reverseZone := newTestZone("10.in-addr.arpa.", true, reverseRecords)
+	zones := []*Zone{forwardZone, reverseZone}
+
+	generateAutoPTRForZones(zones)
+
+	reverseName, err := dns.ReverseAddr("10.0.0.1")
+	if err != nil {
+		t.Fatalf("dns.ReverseAddr: %v", err)
+	}
+
+	fwdRecs := *forwardZone.records.Load()
+	if _, ok := fwdRecs[reverseName]; ok {
+		t.Errorf("PTR for %s was stored in the forward zone, want the reverse zone", reverseName)
+	}
+
+	revRecs := *reverseZone.records.Load()
+	ptrs := revRecs[reverseName]
+	if len(ptrs) != 1 {
+		t.Fatalf("reverse zone has %d records for %s, want 1", len(ptrs), reverseName)
+	}
+	ptr, ok := ptrs[0].(*dns.PTR)
+	if !ok {
+		t.Fatalf("record for %s is a %T, want *dns.PTR", reverseName, ptrs[0])
+	}
+	if ptr.Ptr != "host.internal.corp." {
+		t.Errorf("PTR target = %q, want %q", ptr.Ptr, "host.internal.corp.")
+	}
+}
+
+// TestGenerateAutoPTRForZonesExplicitPTRWins checks that an explicit PTR
+// record already present in the reverse zone isn't clobbered.
+func TestGenerateAutoPTRForZonesExplicitPTRWins(t *testing.T) {
+	reverseName, err := dns.ReverseAddr("10.0.0.1")
+	if err != nil {
+		t.Fatalf("dns.ReverseAddr: %v", err)
+	}
+
+	forwardRecords := map[string][]dns.RR{
+		"host.internal.corp.": {&dns.A{
+			Hdr: dns.RR_Header{Name: "host.internal.corp.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   mustParseIP("10.0.0.1"),
+		}},
+	}
+	explicitPTR := &dns.PTR{
+		Hdr: dns.RR_Header{Name: reverseName, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: 300},
+		Ptr: "explicit.internal.corp.",
+	}
+	reverseRecords := map[string][]dns.RR{reverseName: {explicitPTR}}
+
+	forwardZone := newTestZone("internal.corp.", true, forwardRecords)
+	reverseZone := newTestZone("10.in-addr.arpa.", true, reverseRecords)
+
+	generateAutoPTRForZones([]*Zone{forwardZone, reverseZone})
+
+	revRecs := *reverseZone.records.Load()
+	ptrs := revRecs[reverseName]
+	if len(ptrs) != 1 || ptrs[0] != explicitPTR {
+		t.Errorf("explicit PTR for %s was overwritten: %v", reverseName, ptrs)
+	}
+}
+
+// TestGenerateAutoPTRForZonesDropsStaleOnReload simulates a hot reload that
+// removes the A record a previously synthesized PTR came from, and checks
+// that re-running generateAutoPTRForZones (as reloadZoneByOrigin does)
+// removes the now-stale PTR instead of leaving it behind forever.
+func TestGenerateAutoPTRForZonesDropsStaleOnReload(t *testing.T) {
+	forwardRecords := map[string][]dns.RR{
+		"host.internal.corp.": {&dns.A{
+			Hdr: dns.RR_Header{Name: "host.internal.corp.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   mustParseIP("10.0.0.1"),
+		}},
+	}
+	forwardZone := newTestZone("internal.corp.", true, forwardRecords)
+	reverseZone := newTestZone("10.in-addr.arpa.", true, map[string][]dns.RR{})
+	zones := []*Zone{forwardZone, reverseZone}
+
+	generateAutoPTRForZones(zones)
+
+	reverseName, err := dns.ReverseAddr("10.0.0.1")
+	if err != nil {
+		t.Fatalf("dns.ReverseAddr: %v", err)
+	}
+	if revRecs := *reverseZone.records.Load(); len(revRecs[reverseName]) != 1 {
+		t.Fatalf("expected the PTR to be synthesized before the reload, got %v", revRecs[reverseName])
+	}
+
+	// Simulate a reload of the forward zone that drops the A record.
+	forwardZone.records.Store(&map[string][]dns.RR{})
+	generateAutoPTRForZones(zones)
+
+	revRecs := *reverseZone.records.Load()
+	if ptrs := revRecs[reverseName]; len(ptrs) != 0 {
+		t.Errorf("stale PTR for %s survived the reload: %v", reverseName, ptrs)
+	}
+}
+
+func mustParseIP(s string) net.IP {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		panic("invalid test IP: " + s)
+	}
+	return ip
+}